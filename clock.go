@@ -0,0 +1,132 @@
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is an interface that fits both the standard library's time package
+// and the mock implementation provided here. Code that needs to be testable
+// should take a Clock instead of calling time.Now/time.Sleep/etc directly.
+type Clock interface {
+	After(d time.Duration) <-chan time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+	Now() time.Time
+	Sleep(d time.Duration)
+
+	// SleepContext is like Sleep, but returns ctx.Err() without waiting out
+	// the full duration if ctx is done first.
+	SleepContext(ctx context.Context, d time.Duration) error
+
+	Tick(d time.Duration) <-chan time.Time
+	Ticker(d time.Duration) Ticker
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer mirrors time.Timer, letting code that holds a Clock swap in a mock
+// timer without depending on *time.Timer directly.
+type Timer interface {
+	// C returns the channel the timer fires on.
+	C() <-chan time.Time
+
+	// Stop prevents the Timer from firing. It returns true if the call
+	// stops the timer, false if the timer has already expired or been
+	// stopped.
+	Stop() bool
+
+	// Reset changes the timer to expire after duration d. It returns true
+	// if the timer had been active, false if it had expired or been
+	// stopped.
+	Reset(d time.Duration) bool
+}
+
+// Ticker mirrors time.Ticker, letting code that holds a Clock swap in a mock
+// ticker without depending on *time.Ticker directly.
+type Ticker interface {
+	// C returns the channel the ticker delivers ticks on.
+	C() <-chan time.Time
+
+	// Stop turns off the ticker. It returns true if the call stops the
+	// ticker, false if it had already been stopped.
+	Stop() bool
+
+	// Reset stops the ticker and resets its period to d. It returns true
+	// if the ticker had been active, false if it had already been stopped.
+	Reset(d time.Duration) bool
+}
+
+// New returns a Clock backed by the real system clock.
+func New() Clock {
+	return &realClock{}
+}
+
+type realClock struct{}
+
+func (*realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (*realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (*realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (*realClock) SleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (*realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return &realTimer{time.AfterFunc(d, f)}
+}
+
+func (*realClock) Tick(d time.Duration) <-chan time.Time {
+	return time.Tick(d)
+}
+
+func (*realClock) Ticker(d time.Duration) Ticker {
+	return &realTicker{time.NewTicker(d)}
+}
+
+func (*realClock) NewTimer(d time.Duration) Timer {
+	return &realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (r *realTimer) C() <-chan time.Time { return r.t.C }
+
+func (r *realTimer) Stop() bool { return r.t.Stop() }
+
+func (r *realTimer) Reset(d time.Duration) bool { return r.t.Reset(d) }
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (r *realTicker) C() <-chan time.Time { return r.t.C }
+
+// Stop always returns true: time.Ticker.Stop doesn't report whether the
+// ticker was still running, so neither does this.
+func (r *realTicker) Stop() bool {
+	r.t.Stop()
+	return true
+}
+
+// Reset always returns true; see Stop.
+func (r *realTicker) Reset(d time.Duration) bool {
+	r.t.Reset(d)
+	return true
+}
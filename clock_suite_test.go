@@ -0,0 +1,34 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/101loops/bdd"
+)
+
+// delay is the duration the specs in mock_test.go sleep, tick, and time out
+// by; threshold is how much slack is allowed when comparing elapsed
+// wall-clock time against it.
+const (
+	delay     = 50 * time.Millisecond
+	threshold = 40 * time.Millisecond
+)
+
+// timeDiff returns how far c's time has drifted from the real wall clock,
+// positive if c is behind.
+func timeDiff(c Mock) time.Duration {
+	return time.Now().Sub(c.Now())
+}
+
+// durationOf returns how long f took to run.
+func durationOf(f func()) time.Duration {
+	start := time.Now()
+	f()
+	return time.Now().Sub(start)
+}
+
+// TestClock wires the Describe/It specs in this package into go test.
+func TestClock(t *testing.T) {
+	RunSpecs(t, "Clock Suite")
+}
@@ -1,71 +1,144 @@
 package clock
 
 import (
-	"container/list"
+	"container/heap"
+	"context"
 	"sync"
 	"time"
 )
 
+// realTickInterval is how often a mock's background goroutine checks for
+// elapsed real time while the clock is unfrozen. It bounds how far Now() and
+// scheduled waiters can lag behind the wall clock in unfrozen mode.
+const realTickInterval = time.Millisecond
+
+// Mock is a Clock with additional controls for moving time forward
+// deterministically, either by the wall clock or by explicit Advance calls.
+type Mock interface {
+	Clock
+
+	// Add moves the clock forward by the given duration. It is now an alias
+	// for Advance, kept so existing callers of Add don't need to change.
+	Add(d time.Duration) Mock
+
+	// Advance moves the mock's clock forward by d, firing every waiter
+	// (Sleep, After, ...) whose deadline falls within (now, now+d] in
+	// deadline order before returning. This is deterministic regardless of
+	// the wall clock.
+	Advance(d time.Duration) Mock
+
+	// BlockUntil blocks the calling goroutine until at least n waiters
+	// (goroutines parked in Sleep/After/etc) are registered with the clock.
+	// It's meant to let a test wait for background goroutines to start
+	// waiting on the clock before calling Advance.
+	BlockUntil(n int)
+
+	// Freeze stops the clock from advancing with the wall clock.
+	Freeze() Mock
+
+	// IsFrozen returns whether the clock is currently frozen.
+	IsFrozen() bool
+
+	// Set sets the clock's time to t, firing any waiters whose deadline has
+	// now passed.
+	Set(t time.Time) Mock
+
+	// Unfreeze lets the clock resume advancing with the wall clock.
+	Unfreeze() Mock
+
+	// Trap returns a TrapSet for intercepting calls made against this
+	// clock, so a test can observe and release them one at a time.
+	Trap() *TrapSet
+}
+
+// waiter represents a single pending Sleep/After/Timer/Ticker call. It's
+// scheduled on a mock's waiters heap, ordered by deadline, and fires by
+// invoking fire with the deadline it fired at.
+//
+// A zero repeat means the waiter is one-shot and is dropped once fired. A
+// non-zero repeat means fire immediately reschedules the same waiter for
+// deadline+repeat, which is how Ticker is built on top of the same heap.
 type waiter struct {
-	mutex    sync.Mutex
-	m        *mock
-	wake     chan bool
-	duration time.Duration
-	frozen   bool
-}
-
-func (w *waiter) sleep() {
-	frozen := w.frozen
-
-	wakeAt := w.m.Now().Add(w.duration)
-
-	for {
-		d := wakeAt.Sub(w.m.Now())
-		if d < 0 {
-			break
-		}
-		if frozen {
-			select {
-			case frozen = <-w.wake:
-			}
-		} else {
-			select {
-			case frozen = <-w.wake:
-			case <-time.After(d):
-				return
-			}
-		}
-	}
+	deadline time.Time
+	repeat   time.Duration
+	fire     func(at time.Time)
+	index    int
 }
 
-func (w *waiter) wakeup(freeze bool) {
-	w.wake <- freeze
+// waiterHeap is a container/heap of *waiter ordered by deadline, so the next
+// waiter to fire is always at the root.
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool { return h[i].deadline.Before(h[j].deadline) }
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*h = old[:n-1]
+	return w
 }
 
 type mock struct {
-	mutex  sync.Mutex
+	mutex sync.Mutex
+	cond  *sync.Cond
+
 	base   time.Time
 	last   time.Time
 	frozen bool
 
-	waiters *list.List
+	waiters waiterHeap
+	traps   map[trapKind]*Trap
 }
 
 // NewMock returns a new manipulable Clock.
 func NewMock() Mock {
 	n := time.Now()
-	return &mock{
-		base:    n,
-		last:    n,
-		waiters: list.New(),
+	c := &mock{
+		base: n,
+		last: n,
+	}
+	c.cond = sync.NewCond(&c.mutex)
+	go c.run()
+	return c
+}
+
+// run keeps the clock moving with the wall clock while it's unfrozen, so
+// that waiters still fire on their own even if nothing else calls Now.
+func (c *mock) run() {
+	ticker := time.NewTicker(realTickInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		c.mutex.Lock()
+		c.moveLocked()
+		c.mutex.Unlock()
 	}
 }
 
 func (c *mock) Now() time.Time {
+	c.checkTrap(trapNow, 0)
+
 	defer c.mutex.Unlock()
 	c.mutex.Lock()
 
-	c.move()
+	c.moveLocked()
 	return c.base
 }
 
@@ -75,19 +148,42 @@ func (c *mock) Set(t time.Time) Mock {
 
 	c.base = t
 	c.last = time.Now()
-	c.wakeup()
+	c.fireDueLocked()
 	return c
 }
 
+// Add moves the clock forward by d. It is now an alias for Advance.
 func (c *mock) Add(d time.Duration) Mock {
+	return c.Advance(d)
+}
+
+// Advance moves the clock forward by d, firing waiters in deadline order as
+// their deadlines are crossed.
+func (c *mock) Advance(d time.Duration) Mock {
 	defer c.mutex.Unlock()
 	c.mutex.Lock()
 
-	c.base = c.base.Add(d)
-	c.wakeup()
+	target := c.base.Add(d)
+	for c.waiters.Len() > 0 && !c.waiters[0].deadline.After(target) {
+		w := heap.Pop(&c.waiters).(*waiter)
+		c.base = w.deadline
+		c.fire(w)
+	}
+	c.base = target
+	c.last = time.Now()
+	c.cond.Broadcast()
 	return c
 }
 
+func (c *mock) BlockUntil(n int) {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+
+	for c.waiters.Len() < n {
+		c.cond.Wait()
+	}
+}
+
 func (c *mock) Freeze() Mock {
 	defer c.mutex.Unlock()
 	c.mutex.Lock()
@@ -97,10 +193,9 @@ func (c *mock) Freeze() Mock {
 		return c
 	}
 
-	c.move()
+	c.moveLocked()
 	c.frozen = true
 
-	c.wakeup()
 	return c
 }
 
@@ -118,101 +213,168 @@ func (c *mock) Unfreeze() Mock {
 	c.frozen = false
 	c.last = time.Now()
 
-	c.wakeup()
 	return c
 }
 
-func (c *mock) clear(e *list.Element) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+// moveLocked adjusts base by however much real time has elapsed since the
+// last call, then fires any waiters that are now due. It's a no-op while
+// frozen. c.mutex must be held.
+func (c *mock) moveLocked() {
+	if c.frozen {
+		return
+	}
 
-	c.waiters.Remove(e)
+	n := time.Now()
+	diff := n.Sub(c.last)
+	c.last = n
+	c.base = c.base.Add(diff)
+
+	c.fireDueLocked()
 }
 
-func (c *mock) Sleep(d time.Duration) {
-	c.mutex.Lock()
+// fireDueLocked pops and fires every waiter whose deadline has passed.
+// c.mutex must be held.
+func (c *mock) fireDueLocked() {
+	fired := false
+	for c.waiters.Len() > 0 && !c.waiters[0].deadline.After(c.base) {
+		w := heap.Pop(&c.waiters).(*waiter)
+		c.fire(w)
+		fired = true
+	}
+	if fired {
+		c.cond.Broadcast()
+	}
+}
 
-	w := &waiter{
-		m:        c,
-		wake:     make(chan bool),
-		frozen:   c.frozen,
-		duration: d,
+// fire invokes a waiter's callback with the time it fired at. If the waiter
+// repeats (a Ticker), it's immediately rescheduled for deadline+repeat so
+// that a single Advance can carry it through several ticks. c.mutex must be
+// held.
+func (c *mock) fire(w *waiter) {
+	at := w.deadline
+	w.fire(at)
+
+	if w.repeat > 0 {
+		w.deadline = at.Add(w.repeat)
+		heap.Push(&c.waiters, w)
 	}
-	element := c.waiters.PushBack(w)
-	c.mutex.Unlock()
+}
 
-	defer c.clear(element)
+// newWaiter registers a new waiter deadline away, with the given repeat
+// (zero for one-shot), and returns it. c.mutex must not be held.
+func (c *mock) newWaiter(d, repeat time.Duration, fire func(at time.Time)) *waiter {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
 
-	w.sleep()
-}
+	c.moveLocked()
 
-func (c *mock) wakeup() {
-	for e := c.waiters.Front(); e != nil; e = e.Next() {
-		w := e.Value.(*waiter)
-		w.wakeup(c.frozen)
+	w := &waiter{
+		deadline: c.base.Add(d),
+		repeat:   repeat,
+		fire:     fire,
 	}
+	heap.Push(&c.waiters, w)
+	c.cond.Broadcast()
+
+	return w
 }
 
-func (c *mock) move() {
-	if c.frozen {
-		return
-	}
+// stopWaiter removes w from the heap if it's still pending, preventing it
+// from firing or repeating again. It reports whether w was still pending.
+func (c *mock) stopWaiter(w *waiter) bool {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
 
-	// Adjust the time by the amount of elapsed time since the last call.
-	n := time.Now()
-	diff := n.Sub(c.last)
-	c.last = n
-	c.base = c.base.Add(diff)
+	w.repeat = 0
+	if w.index < 0 {
+		return false
+	}
+	heap.Remove(&c.waiters, w.index)
+	c.cond.Broadcast()
+	return true
 }
 
-func (c *mock) Tick(d time.Duration) <-chan time.Time {
+// resetWaiter reschedules w for d away with the given repeat, removing it
+// from the heap first if it was still pending. It reports whether w was
+// still pending.
+func (c *mock) resetWaiter(w *waiter, d, repeat time.Duration) bool {
+	defer c.mutex.Unlock()
 	c.mutex.Lock()
 
-	w := &waiter{
-		m:        c,
-		wake:     make(chan bool),
-		frozen:   c.frozen,
-		duration: d,
+	active := w.index >= 0
+	if active {
+		heap.Remove(&c.waiters, w.index)
 	}
-	element := c.waiters.PushBack(w)
-	c.mutex.Unlock()
-
-	ch := make(chan time.Time)
-
-	go func() {
-		// Not exactly correct since it doesn't account for slow receivers.
-		for {
-			w.sleep()
-			ch <- c.Now()
-		}
-		c.clear(element)
-	}()
+
+	c.moveLocked()
+	w.deadline = c.base.Add(d)
+	w.repeat = repeat
+	heap.Push(&c.waiters, w)
+	c.cond.Broadcast()
+
+	return active
+}
+
+// schedule registers a one-shot waiter deadline away and returns the
+// channel it will fire on.
+func (c *mock) schedule(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.newWaiter(d, 0, func(at time.Time) { ch <- at })
 	return ch
 }
 
-func (*mock) Ticker(d time.Duration) *time.Ticker {
-	// TODO: make mockable
-	return time.NewTicker(d)
+func (c *mock) Sleep(d time.Duration) {
+	c.checkTrap(trapSleep, d)
+	<-c.schedule(d)
 }
 
-func (c *mock) After(d time.Duration) <-chan time.Time {
-	c.mutex.Lock()
+// SleepContext is like Sleep, but a done ctx wakes the sleeper early with
+// ctx.Err() instead of waiting for the waiter's deadline, removing it from
+// the waiters heap so it doesn't fire or leak.
+func (c *mock) SleepContext(ctx context.Context, d time.Duration) error {
+	c.checkTrap(trapSleep, d)
 
-	w := &waiter{
-		m:        c,
-		wake:     make(chan bool),
-		frozen:   c.frozen,
-		duration: d,
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	element := c.waiters.PushBack(w)
-	c.mutex.Unlock()
 
-	ch := make(chan time.Time)
+	ch := make(chan time.Time, 1)
+	w := c.newWaiter(d, 0, func(at time.Time) { ch <- at })
 
-	go func() {
-		w.sleep()
-		c.clear(element)
-		ch <- c.Now()
-	}()
-	return ch
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		c.stopWaiter(w)
+		return ctx.Err()
+	}
+}
+
+func (c *mock) After(d time.Duration) <-chan time.Time {
+	c.checkTrap(trapAfter, d)
+	return c.schedule(d)
+}
+
+// AfterFunc waits for d to elapse and then calls f in its own goroutine,
+// the same as time.AfterFunc. The returned Timer's Stop cancels the call if
+// it hasn't fired yet.
+func (c *mock) AfterFunc(d time.Duration, f func()) Timer {
+	return newMockFuncTimer(c, d, f)
+}
+
+// Tick is a convenience wrapper around Ticker, for callers that only want
+// the channel. Like time.Tick, there's no way to stop it, so the ticker it
+// wraps runs for as long as the program does. Building it on Ticker means
+// ticks are queued and delivered in order when the receiver falls behind;
+// only past maxQueuedTicks undelivered ticks is the oldest one dropped.
+func (c *mock) Tick(d time.Duration) <-chan time.Time {
+	return newMockTicker(c, d).C()
+}
+
+func (c *mock) Ticker(d time.Duration) Ticker {
+	return newMockTicker(c, d)
+}
+
+func (c *mock) NewTimer(d time.Duration) Timer {
+	return newMockTimer(c, d)
 }
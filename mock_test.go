@@ -1,6 +1,7 @@
 package clock
 
 import (
+	"context"
 	"time"
 
 	. "github.com/101loops/bdd"
@@ -68,4 +69,156 @@ var _ = Describe("Mock Clock", func() {
 		slept := durationOf(func() { clock.Sleep(delay) })
 		Check(slept, IsRoughly, delay, threshold)
 	})
+
+	It("advances past frozen waiters deterministically", func() {
+		clock := NewMock().Freeze().Set(fixedTime)
+
+		var woke time.Time
+		done := make(chan bool)
+		go func() {
+			clock.Sleep(delay)
+			woke = clock.Now()
+			done <- true
+		}()
+
+		clock.BlockUntil(1)
+		clock.Advance(delay)
+		<-done
+
+		Check(woke, IsSameTimeAs, fixedTime.Add(delay))
+	})
+
+	It("fires multiple waiters, each at its own deadline, on a single Advance", func() {
+		clock := NewMock().Freeze().Set(fixedTime)
+
+		// After registers its waiter synchronously, so both waiters are in
+		// place before Advance; reading each returned channel by name checks
+		// that each fired with its own deadline rather than Advance's target.
+		far := clock.After(2 * delay)
+		near := clock.After(delay)
+
+		clock.Advance(2 * delay)
+
+		Check(<-near, IsSameTimeAs, fixedTime.Add(delay))
+		Check(<-far, IsSameTimeAs, fixedTime.Add(2*delay))
+	})
+
+	It("delivers every tick a single Advance spans", func() {
+		clock := NewMock().Freeze().Set(fixedTime)
+
+		ticker := clock.Ticker(delay)
+		clock.BlockUntil(1)
+		clock.Advance(3 * delay)
+
+		for i := 1; i <= 3; i++ {
+			Check(<-ticker.C(), IsSameTimeAs, fixedTime.Add(time.Duration(i)*delay))
+		}
+	})
+
+	It("stops a ticker", func() {
+		clock := NewMock().Freeze().Set(fixedTime)
+
+		ticker := clock.Ticker(delay)
+		clock.BlockUntil(1)
+		Check(ticker.Stop(), IsTrue)
+
+		clock.Advance(3 * delay)
+		select {
+		case <-ticker.C():
+			Check(false, IsTrue) // should not have ticked
+		default:
+		}
+	})
+
+	It("fires a timer and reports whether Stop was still pending", func() {
+		clock := NewMock().Freeze().Set(fixedTime)
+
+		timer := clock.NewTimer(delay)
+		clock.BlockUntil(1)
+		clock.Advance(delay)
+
+		Check(<-timer.C(), IsSameTimeAs, fixedTime.Add(delay))
+		Check(timer.Stop(), IsFalse)
+	})
+
+	It("traps a sleep and releases it on command", func() {
+		clock := NewMock().Freeze().Set(fixedTime)
+
+		trap := clock.Trap().Sleep()
+		defer trap.Close()
+
+		done := make(chan bool)
+		go func() {
+			clock.Sleep(delay)
+			done <- true
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		call := trap.MustWait(ctx)
+		Check(call.Duration(), Equals, delay)
+
+		call.Release()
+		clock.BlockUntil(1)
+		clock.Advance(delay)
+		<-done
+	})
+
+	It("returns ctx.Err() when SleepContext's context is cancelled first", func() {
+		clock := NewMock().Freeze().Set(fixedTime)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		errs := make(chan error, 1)
+		go func() {
+			errs <- clock.SleepContext(ctx, delay)
+		}()
+
+		clock.BlockUntil(1)
+		cancel()
+
+		Check(<-errs, Equals, context.Canceled)
+	})
+
+	It("lets SleepContext complete normally if the deadline fires first", func() {
+		clock := NewMock().Freeze().Set(fixedTime)
+
+		errs := make(chan error, 1)
+		go func() {
+			errs <- clock.SleepContext(context.Background(), delay)
+		}()
+
+		clock.BlockUntil(1)
+		clock.Advance(delay)
+
+		Check(<-errs, IsNil)
+	})
+
+	It("runs AfterFunc's callback when its deadline fires", func() {
+		clock := NewMock().Freeze().Set(fixedTime)
+
+		done := make(chan bool, 1)
+		clock.AfterFunc(delay, func() { done <- true })
+
+		clock.BlockUntil(1)
+		clock.Advance(delay)
+
+		<-done
+	})
+
+	It("prevents AfterFunc's callback from running if stopped in time", func() {
+		clock := NewMock().Freeze().Set(fixedTime)
+
+		called := make(chan bool, 1)
+		timer := clock.AfterFunc(delay, func() { called <- true })
+
+		clock.BlockUntil(1)
+		Check(timer.Stop(), IsTrue)
+		clock.Advance(delay)
+
+		select {
+		case <-called:
+			Check(false, IsTrue) // should not have run
+		default:
+		}
+	})
 })
@@ -0,0 +1,182 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// mockTimer is the Timer returned by mock's NewTimer.
+type mockTimer struct {
+	c  *mock
+	w  *waiter
+	ch chan time.Time
+}
+
+func newMockTimer(c *mock, d time.Duration) *mockTimer {
+	c.checkTrap(trapNewTimer, d)
+
+	t := &mockTimer{c: c, ch: make(chan time.Time, 1)}
+	t.w = c.newWaiter(d, 0, func(at time.Time) {
+		select {
+		case t.ch <- at:
+		default:
+		}
+	})
+	return t
+}
+
+func (t *mockTimer) C() <-chan time.Time { return t.ch }
+
+func (t *mockTimer) Stop() bool { return t.c.stopWaiter(t.w) }
+
+func (t *mockTimer) Reset(d time.Duration) bool { return t.c.resetWaiter(t.w, d, 0) }
+
+// mockFuncTimer is the Timer returned by mock's AfterFunc. It has no C(); f
+// is run directly, in its own goroutine, when the waiter fires.
+type mockFuncTimer struct {
+	c *mock
+	w *waiter
+	f func()
+}
+
+func newMockFuncTimer(c *mock, d time.Duration, f func()) *mockFuncTimer {
+	t := &mockFuncTimer{c: c, f: f}
+	t.w = c.newWaiter(d, 0, func(time.Time) { go t.f() })
+	return t
+}
+
+// C always returns nil; AfterFunc timers don't deliver on a channel.
+func (t *mockFuncTimer) C() <-chan time.Time { return nil }
+
+func (t *mockFuncTimer) Stop() bool { return t.c.stopWaiter(t.w) }
+
+func (t *mockFuncTimer) Reset(d time.Duration) bool { return t.c.resetWaiter(t.w, d, 0) }
+
+// maxQueuedTicks bounds how many undelivered ticks a mockTicker buffers for
+// a receiver that never reads C(). Past this bound, the oldest queued tick
+// is dropped to make room for the newest, the same trade-off time.Ticker's
+// single-slot channel makes, just with a deeper buffer.
+const maxQueuedTicks = 1024
+
+// mockTicker is the Ticker returned by mock's Ticker. Ticks are queued on an
+// internal buffer and delivered to C() in order by a dedicated goroutine, so
+// a slow receiver never blocks the mock's clock and only falls behind up to
+// maxQueuedTicks before the oldest ticks start being dropped. The delivery
+// goroutine exits once Stop leaves the queue empty, rather than idling
+// forever, and Reset respawns it if needed.
+type mockTicker struct {
+	c *mock
+	d time.Duration
+	w *waiter
+
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	queue   []time.Time
+	stopped bool
+	running bool
+	done    chan struct{}
+
+	out chan time.Time
+}
+
+func newMockTicker(c *mock, d time.Duration) *mockTicker {
+	c.checkTrap(trapNewTicker, d)
+
+	t := &mockTicker{c: c, d: d, out: make(chan time.Time), running: true, done: make(chan struct{})}
+	t.cond = sync.NewCond(&t.mutex)
+	t.w = c.newWaiter(d, d, t.enqueue)
+	go t.deliver()
+	return t
+}
+
+func (t *mockTicker) enqueue(at time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.stopped {
+		return
+	}
+	if len(t.queue) >= maxQueuedTicks {
+		t.queue = t.queue[1:]
+	}
+	t.queue = append(t.queue, at)
+	t.cond.Broadcast()
+}
+
+// deliver drains the queue to out in order, one tick at a time, exiting
+// once the ticker is stopped and the queue has run dry so a Stop'd ticker
+// doesn't leave a goroutine parked forever. It also exits a send already in
+// flight as soon as Stop closes done, rather than blocking forever on a
+// receiver that will never come back for a tick queued before the stop.
+// Reset after a Stop installs a fresh done, and starts a fresh deliver
+// goroutine if this one has already exited.
+func (t *mockTicker) deliver() {
+	for {
+		t.mutex.Lock()
+		for len(t.queue) == 0 && !t.stopped {
+			t.cond.Wait()
+		}
+		if len(t.queue) == 0 {
+			t.running = false
+			t.mutex.Unlock()
+			return
+		}
+		at := t.queue[0]
+		t.queue = t.queue[1:]
+		done := t.done
+		t.mutex.Unlock()
+
+		select {
+		case t.out <- at:
+		case <-done:
+			t.mutex.Lock()
+			if !t.stopped {
+				// Reset since the Stop that closed done: drop the tick
+				// queued before the Stop and keep delivering.
+				t.mutex.Unlock()
+				continue
+			}
+			t.running = false
+			t.mutex.Unlock()
+			return
+		}
+	}
+}
+
+func (t *mockTicker) C() <-chan time.Time { return t.out }
+
+func (t *mockTicker) Stop() bool {
+	t.mutex.Lock()
+	wasStopped := t.stopped
+	t.stopped = true
+	if !wasStopped {
+		close(t.done)
+	}
+	t.cond.Broadcast()
+	t.mutex.Unlock()
+
+	active := t.c.stopWaiter(t.w)
+	return active && !wasStopped
+}
+
+func (t *mockTicker) Reset(d time.Duration) bool {
+	t.mutex.Lock()
+	wasStopped := t.stopped
+	t.stopped = false
+	t.d = d
+	if wasStopped {
+		t.done = make(chan struct{})
+	}
+	restart := !t.running
+	if restart {
+		t.running = true
+	}
+	t.mutex.Unlock()
+
+	if restart {
+		go t.deliver()
+	}
+
+	active := t.c.resetWaiter(t.w, d, d)
+	return active && !wasStopped
+}
@@ -0,0 +1,133 @@
+package clock
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestRaceStress hammers a single mock clock from many goroutines at once —
+// some driving it forward (Set/Add/Freeze/Unfreeze), others parking on it
+// (After/Ticker/SleepContext) — so that `go test -race` catches any data race
+// in the waiters heap or a ticker's queue.
+func TestRaceStress(t *testing.T) {
+	clock := NewMock()
+
+	const waiters = 4000
+	const drivers = 50
+
+	var wg sync.WaitGroup
+
+	wg.Add(waiters)
+	for i := 0; i < waiters; i++ {
+		go func(i int) {
+			defer wg.Done()
+			d := time.Duration(i%5+1) * time.Millisecond
+
+			switch i % 3 {
+			case 0:
+				select {
+				case <-clock.After(d):
+				case <-time.After(time.Second):
+				}
+			case 1:
+				ticker := clock.Ticker(d)
+				defer ticker.Stop()
+				select {
+				case <-ticker.C():
+				case <-time.After(time.Second):
+				}
+			case 2:
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				_ = clock.SleepContext(ctx, d)
+			}
+		}(i)
+	}
+
+	wg.Add(drivers)
+	for i := 0; i < drivers; i++ {
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				switch j % 4 {
+				case 0:
+					clock.Add(time.Millisecond)
+				case 1:
+					clock.Set(time.Now())
+				case 2:
+					clock.Freeze()
+				case 3:
+					clock.Unfreeze()
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+// TestTickerStopExitsDeliverGoroutine checks that a stopped ticker's
+// delivery goroutine actually exits instead of idling forever, by watching
+// the process's goroutine count settle back down after many tickers are
+// created and stopped with nothing left to receive their ticks. Advancing
+// past the tick before Stop leaves deliver blocked mid-send on C(), which is
+// the case that actually exercises the abort path rather than just the
+// empty-queue one.
+func TestTickerStopExitsDeliverGoroutine(t *testing.T) {
+	clock := NewMock().Freeze().Set(fixedTime)
+
+	runtime.GC()
+	before := runtime.NumGoroutine()
+
+	const n = 200
+	for i := 0; i < n; i++ {
+		ticker := clock.Ticker(time.Millisecond)
+		clock.BlockUntil(1)
+		clock.Advance(time.Millisecond)
+		ticker.Stop()
+	}
+
+	var after int
+	for i := 0; i < 100; i++ {
+		runtime.GC()
+		after = runtime.NumGoroutine()
+		if after <= before {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("goroutine count grew from %d to %d after stopping %d tickers; deliver appears to leak", before, after, n)
+}
+
+// TestTrapCloseReleasesRacingPush hammers arm-spawn-Close in a tight loop so
+// that, occasionally, Close runs while a trapped call's push has already
+// committed to sending but hasn't reached the calls channel yet. Close must
+// still release it rather than leaving the trapped goroutine blocked
+// forever.
+func TestTrapCloseReleasesRacingPush(t *testing.T) {
+	clock := NewMock().Freeze().Set(fixedTime)
+
+	for i := 0; i < 2000; i++ {
+		trap := clock.Trap().Sleep()
+
+		done := make(chan struct{})
+		go func() {
+			clock.Sleep(time.Millisecond)
+			close(done)
+		}()
+
+		trap.Close()
+		clock.BlockUntil(1)
+		clock.Advance(time.Millisecond)
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("iteration %d: Sleep never returned after Close", i)
+		}
+	}
+}
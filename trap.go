@@ -0,0 +1,157 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// trapKind identifies which mock method a Trap intercepts.
+type trapKind int
+
+const (
+	trapNow trapKind = iota
+	trapSleep
+	trapAfter
+	trapNewTimer
+	trapNewTicker
+)
+
+// Call is a single call into a mock clock that was intercepted by a Trap,
+// captured before it's allowed to proceed.
+type Call struct {
+	kind     trapKind
+	duration time.Duration
+	released chan struct{}
+	once     sync.Once
+}
+
+// Duration returns the duration argument the trapped call was made with.
+// It's zero for a trapped Now call.
+func (c *Call) Duration() time.Duration { return c.duration }
+
+// Release lets the trapped call proceed. It's safe to call more than once.
+func (c *Call) Release() {
+	c.once.Do(func() { close(c.released) })
+}
+
+// Trap intercepts every call of one kind made on a mock clock, so a test
+// can observe each call and release it explicitly instead of relying on
+// time.Sleep to synchronize with the code under test.
+type Trap struct {
+	c     *mock
+	kind  trapKind
+	calls chan *Call
+
+	mutex   sync.Mutex
+	closed  bool
+	pending int
+}
+
+// Wait blocks until a trapped call arrives or ctx is done.
+func (t *Trap) Wait(ctx context.Context) (*Call, error) {
+	select {
+	case call := <-t.calls:
+		return call, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// MustWait is like Wait but panics if ctx ends before a call arrives.
+func (t *Trap) MustWait(ctx context.Context) *Call {
+	call, err := t.Wait(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return call
+}
+
+// Close disarms the trap. Future calls of this kind proceed unimpeded, and
+// every call already blocked on it — including one whose push raced with
+// this Close and hadn't sent on calls yet — is released before Close
+// returns.
+func (t *Trap) Close() {
+	t.c.mutex.Lock()
+	if t.c.traps[t.kind] == t {
+		delete(t.c.traps, t.kind)
+	}
+	t.c.mutex.Unlock()
+
+	t.mutex.Lock()
+	t.closed = true
+	pending := t.pending
+	t.mutex.Unlock()
+
+	// Every push that had already committed to sending (pending was
+	// incremented before closed was set) is guaranteed to still deliver a
+	// call on t.calls, so draining exactly `pending` of them can't block
+	// forever.
+	for i := 0; i < pending; i++ {
+		(<-t.calls).Release()
+	}
+}
+
+// push hands the mutex-protected call through the trap and blocks until
+// the test releases it, unless the trap has been closed in the meantime,
+// in which case it returns immediately without sending. c.mutex must not
+// be held.
+func (t *Trap) push(duration time.Duration) {
+	t.mutex.Lock()
+	if t.closed {
+		t.mutex.Unlock()
+		return
+	}
+	t.pending++
+	t.mutex.Unlock()
+
+	call := &Call{kind: t.kind, duration: duration, released: make(chan struct{})}
+	t.calls <- call
+	<-call.released
+
+	t.mutex.Lock()
+	t.pending--
+	t.mutex.Unlock()
+}
+
+// TrapSet builds traps for a mock clock's methods, one kind at a time.
+type TrapSet struct {
+	c *mock
+}
+
+// Trap returns a TrapSet for arming traps on this clock.
+func (c *mock) Trap() *TrapSet {
+	return &TrapSet{c: c}
+}
+
+func (s *TrapSet) Now() *Trap       { return s.c.arm(trapNow) }
+func (s *TrapSet) Sleep() *Trap     { return s.c.arm(trapSleep) }
+func (s *TrapSet) After() *Trap     { return s.c.arm(trapAfter) }
+func (s *TrapSet) NewTimer() *Trap  { return s.c.arm(trapNewTimer) }
+func (s *TrapSet) NewTicker() *Trap { return s.c.arm(trapNewTicker) }
+
+// arm installs a trap for kind, replacing any existing one of the same
+// kind.
+func (c *mock) arm(kind trapKind) *Trap {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+
+	if c.traps == nil {
+		c.traps = make(map[trapKind]*Trap)
+	}
+	t := &Trap{c: c, kind: kind, calls: make(chan *Call)}
+	c.traps[kind] = t
+	return t
+}
+
+// checkTrap blocks the caller if a trap is armed for kind, delivering a
+// *Call for the test to observe and release. c.mutex must not be held.
+func (c *mock) checkTrap(kind trapKind, duration time.Duration) {
+	c.mutex.Lock()
+	t, ok := c.traps[kind]
+	c.mutex.Unlock()
+
+	if ok {
+		t.push(duration)
+	}
+}